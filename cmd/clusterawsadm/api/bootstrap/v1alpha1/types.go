@@ -0,0 +1,236 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 contains the configuration consumed by clusterawsadm to
+// render the bootstrap IAM CloudFormation/Terraform resources.
+package v1alpha1
+
+import (
+	iamv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/iam/v1alpha1"
+)
+
+const (
+	// DefaultNameSuffix is the default suffix appended to the name of
+	// every managed IAM resource.
+	DefaultNameSuffix = ".cluster-api-provider-aws.sigs.k8s.io"
+)
+
+// AWSIAMConfiguration is the configuration for bootstrapping IAM
+// users/groups/roles/policies for use by Cluster API Provider AWS.
+type AWSIAMConfiguration struct {
+	Spec AWSIAMConfigurationSpec `json:"spec,omitempty"`
+}
+
+// AWSIAMConfigurationSpec defines the configuration used to render the
+// bootstrap IAM resources.
+type AWSIAMConfigurationSpec struct {
+	// NamePrefix is prepended to every managed IAM resource name.
+	NamePrefix string `json:"namePrefix,omitempty"`
+	// NameSuffix is appended to every managed IAM resource name.
+	NameSuffix *string `json:"nameSuffix,omitempty"`
+
+	// BootstrapUser controls the creation of an IAM user that can
+	// bootstrap the rest of the roles and policies.
+	BootstrapUser BootstrapUser `json:"bootstrapUser,omitempty"`
+	// ControlPlane controls the configuration of the AWS IAM role for
+	// the Kubernetes control plane.
+	ControlPlane ControlPlaneAWSIAMRoleSpec `json:"controlPlane,omitempty"`
+	// ClusterAPIControllers controls the configuration of the AWS IAM
+	// role used by the Cluster API Provider AWS controllers themselves.
+	ClusterAPIControllers AWSIAMRoleSpec `json:"clusterAPIControllers,omitempty"`
+	// Nodes controls the configuration of the AWS IAM role for worker
+	// nodes.
+	Nodes NodeAWSIAMRoleSpec `json:"nodes,omitempty"`
+	// ManagedControlPlane controls the configuration of the AWS IAM
+	// role used by EKS-managed control planes.
+	ManagedControlPlane ManagedControlPlaneAWSIAMRoleSpec `json:"managedControlPlane,omitempty"`
+
+	// OIDCProviders lists the OIDC identity providers that IAM Roles for
+	// Service Accounts (IRSA) roles below may be federated with.
+	OIDCProviders []OIDCProvider `json:"oidcProviders,omitempty"`
+	// ControllerIAMRoles lists additional IAM roles to create, trusted
+	// via IRSA, for controllers/service accounts that should run with
+	// pod-scoped credentials instead of the node instance profile.
+	ControllerIAMRoles []ControllerIAMRole `json:"controllerIAMRoles,omitempty"`
+
+	// WellKnownPolicies enables pre-authored managed policies (and, when
+	// OIDCProviders are configured, matching IRSA roles) for common EKS
+	// addons, mirroring eksctl's WellKnownPolicies.
+	WellKnownPolicies WellKnownPolicies `json:"wellKnownPolicies,omitempty"`
+}
+
+// WellKnownPolicies toggles pre-authored managed policies for common EKS
+// addons. Enabling one of these creates a managed policy whose statements
+// are built from iamv1.PolicyDocument/StatementEntry. The policy is
+// attached only to the addon's IRSA role, which is created when
+// OIDCProviders are configured; without them the policy is created
+// unattached. It is not added to any role's ExtraPolicyAttachments.
+type WellKnownPolicies struct {
+	// AWSLoadBalancerController grants the permissions required by the
+	// AWS Load Balancer Controller addon.
+	AWSLoadBalancerController bool `json:"awsLoadBalancerController,omitempty"`
+	// ClusterAutoscaler grants the permissions required by the
+	// Kubernetes Cluster Autoscaler to discover and resize node groups.
+	ClusterAutoscaler bool `json:"clusterAutoscaler,omitempty"`
+	// ExternalDNS grants the permissions required by external-dns to
+	// manage Route 53 records.
+	ExternalDNS bool `json:"externalDNS,omitempty"`
+	// CertManager grants the permissions required by cert-manager's
+	// Route 53 DNS01 solver.
+	CertManager bool `json:"certManager,omitempty"`
+	// EBSCSIController grants the permissions required by the AWS EBS
+	// CSI driver controller.
+	EBSCSIController bool `json:"ebsCSIController,omitempty"`
+	// EFSCSIController grants the permissions required by the AWS EFS
+	// CSI driver controller.
+	EFSCSIController bool `json:"efsCSIController,omitempty"`
+	// ImageBuilder grants the permissions required by EKS image builder
+	// pipelines to publish AMIs.
+	ImageBuilder bool `json:"imageBuilder,omitempty"`
+	// AutoScaler grants the EC2 Auto Scaling group permissions used by
+	// node-group scaling controllers that run independently of the
+	// Kubernetes-aware Cluster Autoscaler above.
+	AutoScaler bool `json:"autoScaler,omitempty"`
+}
+
+// OIDCProvider identifies an OIDC identity provider that a federated IAM
+// role's trust policy may reference. This package only renders
+// CloudFormation/Terraform documents and makes no AWS API calls, so a
+// provider must already exist; discovering or creating one from an EKS
+// cluster is out of scope here and belongs in whatever command
+// provisions the cluster.
+type OIDCProvider struct {
+	// ARN is the ARN of an existing IAM OIDC identity provider, of the
+	// form "arn:aws:iam::<account>:oidc-provider/<issuer-host-path>".
+	ARN string `json:"arn"`
+}
+
+// ServiceAccount identifies the Kubernetes service account an IRSA role's
+// trust policy should be scoped to.
+type ServiceAccount struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// ControllerIAMRole describes one IAM role, trusted by an OIDCProvider via
+// IRSA, for a controller running as a specific Kubernetes service account.
+type ControllerIAMRole struct {
+	// Name identifies the role and is used to derive both its
+	// CloudFormation logical resource name and its managed IAM role name.
+	Name string `json:"name"`
+	// ServiceAccount is the Kubernetes service account the role's trust
+	// policy is scoped to.
+	ServiceAccount ServiceAccount `json:"serviceAccount"`
+	// ManagedPolicyArns are the managed policies attached to the role.
+	ManagedPolicyArns []string `json:"managedPolicyArns,omitempty"`
+}
+
+// BootstrapUser controls the creation of an IAM user for bootstrapping.
+type BootstrapUser struct {
+	Enable    bool              `json:"enable,omitempty"`
+	UserName  string            `json:"userName,omitempty"`
+	GroupName string            `json:"groupName,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// AWSIAMRoleSpec defines common configuration for a managed IAM role.
+type AWSIAMRoleSpec struct {
+	ExtraStatements        []string          `json:"extraStatements,omitempty"`
+	ExtraPolicyAttachments []string          `json:"extraPolicyAttachments,omitempty"`
+	Tags                   map[string]string `json:"tags,omitempty"`
+
+	// TrustPolicy, when set, composes this role's AssumeRolePolicyDocument
+	// from multiple principals (AWS service, cross-account, and/or OIDC
+	// federated) instead of the default single service principal.
+	TrustPolicy *TrustPolicy `json:"trustPolicy,omitempty"`
+}
+
+// TrustPolicy composes a role's AssumeRolePolicyDocument from any mix of
+// service, AWS account/role, and OIDC federated principals, the
+// equivalent of CDK's CompositePrincipal.
+type TrustPolicy struct {
+	// Services are AWS service principals, e.g. "ec2.amazonaws.com".
+	Services []string `json:"services,omitempty"`
+	// AWSPrincipals are AWS account root or IAM user/role ARNs.
+	AWSPrincipals []string `json:"awsPrincipals,omitempty"`
+	// FederatedPrincipals are OIDC identity providers trusted via
+	// sts:AssumeRoleWithWebIdentity.
+	FederatedPrincipals []FederatedPrincipal `json:"federatedPrincipals,omitempty"`
+	// Conditions are applied to every statement generated from this
+	// trust policy.
+	Conditions iamv1.Conditions `json:"conditions,omitempty"`
+}
+
+// FederatedPrincipal identifies an OIDC identity provider trusted by a
+// TrustPolicy.
+type FederatedPrincipal struct {
+	// ProviderARN is the ARN of the IAM OIDC identity provider.
+	ProviderARN string `json:"providerARN"`
+}
+
+// ControlPlaneAWSIAMRoleSpec defines configuration for the control plane
+// IAM role.
+type ControlPlaneAWSIAMRoleSpec struct {
+	AWSIAMRoleSpec `json:",inline"`
+
+	// DisableCloudProviderPolicy disables attaching the managed policy
+	// required by the AWS cloud provider to the control plane role.
+	DisableCloudProviderPolicy bool `json:"disableCloudProviderPolicy,omitempty"`
+	// EnableCSIPolicy attaches the managed policy required by the AWS
+	// EBS CSI driver to the control plane role.
+	EnableCSIPolicy bool `json:"enableCSIPolicy,omitempty"`
+	// EnableDLMPolicy provisions an AWS Data Lifecycle Manager role and
+	// managed policy for automated EBS snapshot management of
+	// CAPA-managed volumes.
+	EnableDLMPolicy bool `json:"enableDLMPolicy,omitempty"`
+}
+
+// NodeAWSIAMRoleSpec defines configuration for the worker node IAM role.
+type NodeAWSIAMRoleSpec struct {
+	AWSIAMRoleSpec `json:",inline"`
+
+	// DisableCloudProviderPolicy disables attaching the managed policy
+	// required by the AWS cloud provider to the node role.
+	DisableCloudProviderPolicy bool `json:"disableCloudProviderPolicy,omitempty"`
+}
+
+// ManagedControlPlaneAWSIAMRoleSpec defines configuration for the
+// EKS-managed control plane IAM role.
+type ManagedControlPlaneAWSIAMRoleSpec struct {
+	// Disable skips creation of the EKS-managed control plane IAM role.
+	Disable bool              `json:"disable,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+
+	// TrustPolicy, when set, composes this role's AssumeRolePolicyDocument
+	// from multiple principals instead of the default eks.amazonaws.com
+	// service principal alone.
+	TrustPolicy *TrustPolicy `json:"trustPolicy,omitempty"`
+}
+
+// NewAWSIAMConfiguration creates a new AWSIAMConfiguration with default
+// values populated.
+func NewAWSIAMConfiguration() *AWSIAMConfiguration {
+	suffix := DefaultNameSuffix
+	return &AWSIAMConfiguration{
+		Spec: AWSIAMConfigurationSpec{
+			NameSuffix: &suffix,
+			BootstrapUser: BootstrapUser{
+				UserName:  "bootstrapper.cluster-api-provider-aws.sigs.k8s.io",
+				GroupName: "bootstrapper.cluster-api-provider-aws.sigs.k8s.io",
+			},
+		},
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 contains the IAM policy document types shared by the
+// CloudFormation and Terraform renderers in
+// sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/cloudformation/bootstrap.
+package v1alpha1
+
+// CurrentVersion is the only IAM policy document version AWS accepts.
+const CurrentVersion = "2012-10-17"
+
+// Effect is the Effect of a StatementEntry.
+type Effect string
+
+const (
+	// EffectAllow allows the described action.
+	EffectAllow = Effect("Allow")
+	// EffectDeny denies the described action.
+	EffectDeny = Effect("Deny")
+)
+
+// PrincipalType is the kind of principal referenced by a StatementEntry.
+type PrincipalType string
+
+const (
+	// PrincipalAWS is an account, user, or role principal.
+	PrincipalAWS = PrincipalType("AWS")
+	// PrincipalService is an AWS service principal (e.g. ec2.amazonaws.com).
+	PrincipalService = PrincipalType("Service")
+	// PrincipalFederated is a federated identity provider principal,
+	// such as an OIDC or SAML provider ARN.
+	PrincipalFederated = PrincipalType("Federated")
+)
+
+// PrincipalID is a list of identifiers for a given PrincipalType.
+type PrincipalID []string
+
+// Principals is a map of principal types to their identifiers.
+type Principals map[PrincipalType]PrincipalID
+
+// Actions is a list of IAM action strings, e.g. "sts:AssumeRole".
+type Actions []string
+
+// Conditions is a map of IAM condition operators (e.g. "StringEquals") to
+// the key/value pairs they test.
+type Conditions map[string]map[string]string
+
+// StatementEntry is a single statement in a PolicyDocument.
+type StatementEntry struct {
+	Sid        string     `json:"Sid,omitempty"`
+	Effect     Effect     `json:"Effect"`
+	Principal  Principals `json:"Principal,omitempty"`
+	NotActions Actions    `json:"NotAction,omitempty"`
+	Action     Actions    `json:"Action,omitempty"`
+	Resource   Actions    `json:"Resource,omitempty"`
+	Condition  Conditions `json:"Condition,omitempty"`
+}
+
+// PolicyDocument represents an AWS IAM policy document.
+type PolicyDocument struct {
+	Version   string           `json:"Version"`
+	Statement []StatementEntry `json:"Statement"`
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"fmt"
+	"strings"
+
+	cfn_iam "github.com/awslabs/goformation/v4/cloudformation/iam"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/bootstrap/v1alpha1"
+	iamv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/iam/v1alpha1"
+)
+
+// controllerIAMRoleResourceName derives the CloudFormation logical
+// resource name for an IRSA-trusted ControllerIAMRole.
+func controllerIAMRoleResourceName(name string) string {
+	return fmt.Sprintf("AWSIAMRole%sIRSA", kebabToPascal(name))
+}
+
+// federatedTrustPolicy builds an AssumeRolePolicyDocument that trusts the
+// given OIDC provider as a Federated principal, scoped via
+// sts:AssumeRoleWithWebIdentity to the named Kubernetes service account.
+// This is the mechanism behind IAM Roles for Service Accounts (IRSA): it
+// lets a pod running as that service account assume the role without any
+// node-level credentials.
+func federatedTrustPolicy(provider bootstrapv1.OIDCProvider, sa bootstrapv1.ServiceAccount) (*iamv1.PolicyDocument, error) {
+	if provider.ARN == "" {
+		return nil, fmt.Errorf("OIDC provider for service account %s/%s has no ARN", sa.Namespace, sa.Name)
+	}
+
+	issuer, err := oidcIssuerFromProviderARN(provider.ARN)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := fmt.Sprintf("system:serviceaccount:%s:%s", sa.Namespace, sa.Name)
+	return &iamv1.PolicyDocument{
+		Version: iamv1.CurrentVersion,
+		Statement: []iamv1.StatementEntry{
+			{
+				Effect:    iamv1.EffectAllow,
+				Principal: iamv1.Principals{iamv1.PrincipalFederated: iamv1.PrincipalID{provider.ARN}},
+				Action:    iamv1.Actions{"sts:AssumeRoleWithWebIdentity"},
+				Condition: iamv1.Conditions{
+					"StringEquals": {
+						fmt.Sprintf("%s:sub", issuer): subject,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// oidcIssuerFromProviderARN extracts the issuer host/path
+// ("oidc.eks.<region>.amazonaws.com/id/<id>") from an IAM OIDC provider
+// ARN, which is what IAM condition keys like "<issuer>:sub" are keyed on.
+func oidcIssuerFromProviderARN(arn string) (string, error) {
+	const marker = ":oidc-provider/"
+	idx := strings.Index(arn, marker)
+	if idx == -1 {
+		return "", fmt.Errorf("invalid OIDC provider ARN %q: missing %q", arn, marker)
+	}
+	return arn[idx+len(marker):], nil
+}
+
+// controllerIAMRoles builds the AWS::IAM::Role resources for
+// Spec.ControllerIAMRoles, trusted via IRSA against Spec.OIDCProviders, so
+// CAPA controllers can run with pod-scoped credentials instead of the
+// node instance profile. It returns an empty map if no OIDC providers are
+// configured.
+func (t Template) controllerIAMRoles() (map[string]*cfn_iam.Role, error) {
+	roles := map[string]*cfn_iam.Role{}
+	if len(t.Spec.OIDCProviders) == 0 {
+		return roles, nil
+	}
+
+	for _, cr := range t.Spec.ControllerIAMRoles {
+		var statements []iamv1.StatementEntry
+		for _, provider := range t.Spec.OIDCProviders {
+			doc, err := federatedTrustPolicy(provider, cr.ServiceAccount)
+			if err != nil {
+				return nil, fmt.Errorf("building trust policy for controller role %s: %w", cr.Name, err)
+			}
+			statements = append(statements, doc.Statement...)
+		}
+
+		roles[controllerIAMRoleResourceName(cr.Name)] = &cfn_iam.Role{
+			RoleName: t.NewManagedName(cr.Name + "-irsa"),
+			AssumeRolePolicyDocument: &iamv1.PolicyDocument{
+				Version:   iamv1.CurrentVersion,
+				Statement: statements,
+			},
+			ManagedPolicyArns: cr.ManagedPolicyArns,
+		}
+	}
+
+	return roles, nil
+}
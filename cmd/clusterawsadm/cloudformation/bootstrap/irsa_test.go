@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"testing"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/bootstrap/v1alpha1"
+	iamv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/iam/v1alpha1"
+)
+
+func TestOidcIssuerFromProviderARN(t *testing.T) {
+	arn := "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-1.amazonaws.com/id/EXAMPLE"
+	got, err := oidcIssuerFromProviderARN(arn)
+	if err != nil {
+		t.Fatalf("oidcIssuerFromProviderARN() error = %v", err)
+	}
+	want := "oidc.eks.us-east-1.amazonaws.com/id/EXAMPLE"
+	if got != want {
+		t.Errorf("oidcIssuerFromProviderARN() = %q, want %q", got, want)
+	}
+
+	if _, err := oidcIssuerFromProviderARN("not-an-arn"); err == nil {
+		t.Error("oidcIssuerFromProviderARN() with an invalid ARN expected an error, got nil")
+	}
+}
+
+func TestFederatedTrustPolicy(t *testing.T) {
+	provider := bootstrapv1.OIDCProvider{ARN: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-1.amazonaws.com/id/EXAMPLE"}
+	sa := bootstrapv1.ServiceAccount{Namespace: "kube-system", Name: "capa-controller-manager"}
+
+	doc, err := federatedTrustPolicy(provider, sa)
+	if err != nil {
+		t.Fatalf("federatedTrustPolicy() error = %v", err)
+	}
+
+	if len(doc.Statement) != 1 {
+		t.Fatalf("federatedTrustPolicy() statements = %d, want 1", len(doc.Statement))
+	}
+	stmt := doc.Statement[0]
+	if stmt.Principal[iamv1.PrincipalFederated][0] != provider.ARN {
+		t.Errorf("federatedTrustPolicy() Federated principal = %v, want %q", stmt.Principal[iamv1.PrincipalFederated], provider.ARN)
+	}
+	wantSub := "oidc.eks.us-east-1.amazonaws.com/id/EXAMPLE:sub"
+	if stmt.Condition["StringEquals"][wantSub] != "system:serviceaccount:kube-system:capa-controller-manager" {
+		t.Errorf("federatedTrustPolicy() condition = %v, missing expected subject for key %q", stmt.Condition, wantSub)
+	}
+}
+
+func TestFederatedTrustPolicyNoARN(t *testing.T) {
+	_, err := federatedTrustPolicy(bootstrapv1.OIDCProvider{}, bootstrapv1.ServiceAccount{Namespace: "kube-system", Name: "x"})
+	if err == nil {
+		t.Error("federatedTrustPolicy() with no ARN expected an error, got nil")
+	}
+}
+
+func TestControllerIAMRoleResourceName(t *testing.T) {
+	got := controllerIAMRoleResourceName("my-controller")
+	want := "AWSIAMRoleMyControllerIRSA"
+	if got != want {
+		t.Errorf("controllerIAMRoleResourceName() = %q, want %q", got, want)
+	}
+}
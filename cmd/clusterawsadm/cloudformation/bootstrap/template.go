@@ -28,6 +28,10 @@ import (
 	infrav1exp "sigs.k8s.io/cluster-api-provider-aws/exp/api/v1alpha3"
 )
 
+// PolicyName is the CloudFormation logical resource name of a managed IAM
+// policy.
+type PolicyName string
+
 const (
 	AWSIAMGroupBootstrapper                      = "AWSIAMGroupBootstrapper"
 	AWSIAMInstanceProfileControllers             = "AWSIAMInstanceProfileControllers"
@@ -37,11 +41,13 @@ const (
 	AWSIAMRoleControlPlane                       = "AWSIAMRoleControlPlane"
 	AWSIAMRoleNodes                              = "AWSIAMRoleNodes"
 	AWSIAMRoleEKSControlPlane                    = "AWSIAMRoleEKSControlPlane"
+	AWSIAMRoleDLMLifecycle                       = "AWSIAMRoleDLMLifecycle"
 	AWSIAMUserBootstrapper                       = "AWSIAMUserBootstrapper"
 	ControllersPolicy                 PolicyName = "AWSIAMManagedPolicyControllers"
 	ControlPlanePolicy                PolicyName = "AWSIAMManagedPolicyCloudProviderControlPlane"
 	NodePolicy                        PolicyName = "AWSIAMManagedPolicyCloudProviderNodes"
 	CSIPolicy                         PolicyName = "AWSEBSCSIPolicyController"
+	DLMPolicy                         PolicyName = "AWSDLMLifecyclePolicy"
 )
 
 type Template struct {
@@ -63,7 +69,7 @@ func (t Template) NewManagedName(name string) string {
 
 // Template is an AWS CloudFormation template to bootstrap
 // IAM policies, users and roles for use by Cluster API Provider AWS
-func (t Template) RenderCloudFormation() *cloudformation.Template {
+func (t Template) RenderCloudFormation() (*cloudformation.Template, error) {
 	template := cloudformation.NewTemplate()
 
 	if t.Spec.BootstrapUser.Enable {
@@ -115,6 +121,20 @@ func (t Template) RenderCloudFormation() *cloudformation.Template {
 		}
 	}
 
+	if t.Spec.ControlPlane.EnableDLMPolicy {
+		template.Resources[AWSIAMRoleDLMLifecycle] = &cfn_iam.Role{
+			RoleName:                 t.NewManagedName("dlm-lifecycle"),
+			AssumeRolePolicyDocument: dlmAssumeRolePolicy(),
+		}
+
+		template.Resources[string(DLMPolicy)] = &cfn_iam.ManagedPolicy{
+			ManagedPolicyName: t.NewManagedName("dlm-lifecycle"),
+			Description:       `For AWS Data Lifecycle Manager to manage CAPA-created EBS snapshots`,
+			PolicyDocument:    dlmLifecyclePolicy(),
+			Roles:             []string{cloudformation.Ref(AWSIAMRoleDLMLifecycle)},
+		}
+	}
+
 	template.Resources[AWSIAMRoleControlPlane] = &cfn_iam.Role{
 		RoleName:                 t.NewManagedName("control-plane"),
 		AssumeRolePolicyDocument: t.controlPlaneTrustPolicy(),
@@ -161,28 +181,96 @@ func (t Template) RenderCloudFormation() *cloudformation.Template {
 	if !t.Spec.ManagedControlPlane.Disable {
 		template.Resources[AWSIAMRoleEKSControlPlane] = &cfn_iam.Role{
 			RoleName:                 infrav1exp.DefaultEKSControlPlaneRole,
-			AssumeRolePolicyDocument: eksAssumeRolePolicy(),
+			AssumeRolePolicyDocument: t.eksAssumeRolePolicy(),
 			ManagedPolicyArns:        t.eksControlPlanePolicies(),
 			Tags:                     converters.MapToCloudFormationTags(t.Spec.ManagedControlPlane.Tags),
 		}
 	}
 
-	return template
+	irsaRoles, err := t.controllerIAMRoles()
+	if err != nil {
+		return nil, err
+	}
+	for name, role := range irsaRoles {
+		template.Resources[name] = role
+	}
+
+	wellKnownResources, err := t.wellKnownPolicyResources()
+	if err != nil {
+		return nil, err
+	}
+	for name, resource := range wellKnownResources {
+		template.Resources[name] = resource
+	}
+
+	return template, nil
 }
 
-func ec2AssumeRolePolicy() *iamv1.PolicyDocument {
-	return assumeRolePolicy("ec2.amazonaws.com")
+func dlmAssumeRolePolicy() *iamv1.PolicyDocument {
+	return assumeRolePolicy(bootstrapv1.TrustPolicy{Services: []string{"dlm.amazonaws.com"}})
 }
 
-func assumeRolePolicy(principalID string) *iamv1.PolicyDocument {
+// dlmLifecyclePolicy grants AWS Data Lifecycle Manager the permissions it
+// needs to create, tag, and retire automated EBS snapshots of
+// CAPA-managed volumes. Only ec2:CreateTags supports resource-level
+// permissions scoped to the snapshot ARN; the other actions require
+// Resource "*", matching AWS's own AWSDataLifecycleManagerServiceRole
+// policy.
+func dlmLifecyclePolicy() *iamv1.PolicyDocument {
 	return &iamv1.PolicyDocument{
 		Version: iamv1.CurrentVersion,
 		Statement: []iamv1.StatementEntry{
 			{
-				Effect:    iamv1.EffectAllow,
-				Principal: iamv1.Principals{iamv1.PrincipalService: iamv1.PrincipalID{principalID}},
-				Action:    iamv1.Actions{"sts:AssumeRole"},
+				Effect: iamv1.EffectAllow,
+				Action: iamv1.Actions{
+					"ec2:CreateSnapshot",
+					"ec2:DeleteSnapshot",
+					"ec2:DescribeVolumes",
+					"ec2:DescribeSnapshots",
+				},
+				Resource: iamv1.Actions{"*"},
+			},
+			{
+				Effect:   iamv1.EffectAllow,
+				Action:   iamv1.Actions{"ec2:CreateTags"},
+				Resource: iamv1.Actions{"arn:aws:ec2:*::snapshot/*"},
 			},
 		},
 	}
 }
+
+// assumeRolePolicy builds an AssumeRolePolicyDocument from a TrustPolicy.
+// Service and AWS principals share a single sts:AssumeRole statement;
+// each Federated principal gets its own sts:AssumeRoleWithWebIdentity
+// statement, since web-identity federation needs a different action and
+// condition.
+func assumeRolePolicy(trust bootstrapv1.TrustPolicy) *iamv1.PolicyDocument {
+	doc := &iamv1.PolicyDocument{Version: iamv1.CurrentVersion}
+
+	if len(trust.Services) > 0 || len(trust.AWSPrincipals) > 0 {
+		principal := iamv1.Principals{}
+		if len(trust.Services) > 0 {
+			principal[iamv1.PrincipalService] = iamv1.PrincipalID(trust.Services)
+		}
+		if len(trust.AWSPrincipals) > 0 {
+			principal[iamv1.PrincipalAWS] = iamv1.PrincipalID(trust.AWSPrincipals)
+		}
+		doc.Statement = append(doc.Statement, iamv1.StatementEntry{
+			Effect:    iamv1.EffectAllow,
+			Principal: principal,
+			Action:    iamv1.Actions{"sts:AssumeRole"},
+			Condition: trust.Conditions,
+		})
+	}
+
+	for _, fp := range trust.FederatedPrincipals {
+		doc.Statement = append(doc.Statement, iamv1.StatementEntry{
+			Effect:    iamv1.EffectAllow,
+			Principal: iamv1.Principals{iamv1.PrincipalFederated: iamv1.PrincipalID{fp.ProviderARN}},
+			Action:    iamv1.Actions{"sts:AssumeRoleWithWebIdentity"},
+			Condition: trust.Conditions,
+		})
+	}
+
+	return doc
+}
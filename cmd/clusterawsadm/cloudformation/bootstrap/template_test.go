@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"testing"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/bootstrap/v1alpha1"
+	iamv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/iam/v1alpha1"
+)
+
+func TestAssumeRolePolicyComposite(t *testing.T) {
+	doc := assumeRolePolicy(bootstrapv1.TrustPolicy{
+		Services:      []string{"ec2.amazonaws.com"},
+		AWSPrincipals: []string{"arn:aws:iam::111111111111:role/management"},
+		FederatedPrincipals: []bootstrapv1.FederatedPrincipal{
+			{ProviderARN: "arn:aws:iam::222222222222:oidc-provider/oidc.eks.us-east-1.amazonaws.com/id/EXAMPLE"},
+		},
+	})
+
+	if len(doc.Statement) != 2 {
+		t.Fatalf("assumeRolePolicy() statements = %d, want 2 (one combined Service/AWS, one Federated)", len(doc.Statement))
+	}
+
+	combined := doc.Statement[0]
+	if combined.Principal[iamv1.PrincipalService][0] != "ec2.amazonaws.com" {
+		t.Errorf("assumeRolePolicy() Service principal = %v, want ec2.amazonaws.com", combined.Principal[iamv1.PrincipalService])
+	}
+	if combined.Principal[iamv1.PrincipalAWS][0] != "arn:aws:iam::111111111111:role/management" {
+		t.Errorf("assumeRolePolicy() AWS principal = %v, want management role ARN", combined.Principal[iamv1.PrincipalAWS])
+	}
+
+	federated := doc.Statement[1]
+	if federated.Action[0] != "sts:AssumeRoleWithWebIdentity" {
+		t.Errorf("assumeRolePolicy() federated statement action = %v, want sts:AssumeRoleWithWebIdentity", federated.Action)
+	}
+}
+
+func TestMergeTrustPolicy(t *testing.T) {
+	base := bootstrapv1.TrustPolicy{Services: []string{"ec2.amazonaws.com"}}
+	override := &bootstrapv1.TrustPolicy{AWSPrincipals: []string{"arn:aws:iam::111111111111:role/management"}}
+
+	merged := mergeTrustPolicy(base, override)
+	if len(merged.Services) != 1 || merged.Services[0] != "ec2.amazonaws.com" {
+		t.Errorf("mergeTrustPolicy() Services = %v, want the base default preserved", merged.Services)
+	}
+	if len(merged.AWSPrincipals) != 1 || merged.AWSPrincipals[0] != "arn:aws:iam::111111111111:role/management" {
+		t.Errorf("mergeTrustPolicy() AWSPrincipals = %v, want the override applied", merged.AWSPrincipals)
+	}
+
+	if got := mergeTrustPolicy(base, nil); len(got.Services) != 1 {
+		t.Errorf("mergeTrustPolicy() with nil override = %v, want the base unchanged", got)
+	}
+}
+
+func TestDlmLifecyclePolicyResourceScoping(t *testing.T) {
+	doc := dlmLifecyclePolicy()
+
+	if len(doc.Statement) != 2 {
+		t.Fatalf("dlmLifecyclePolicy() statements = %d, want 2 (wildcard actions separate from CreateTags)", len(doc.Statement))
+	}
+
+	wildcard := doc.Statement[0]
+	for _, action := range []string{"ec2:CreateSnapshot", "ec2:DeleteSnapshot", "ec2:DescribeVolumes", "ec2:DescribeSnapshots"} {
+		if !containsAction(wildcard.Action, action) {
+			t.Errorf("dlmLifecyclePolicy() wildcard statement missing action %s", action)
+		}
+	}
+	if len(wildcard.Resource) != 1 || wildcard.Resource[0] != "*" {
+		t.Errorf("dlmLifecyclePolicy() wildcard statement Resource = %v, want [\"*\"]", wildcard.Resource)
+	}
+
+	createTags := doc.Statement[1]
+	if len(createTags.Action) != 1 || createTags.Action[0] != "ec2:CreateTags" {
+		t.Errorf("dlmLifecyclePolicy() second statement Action = %v, want only ec2:CreateTags", createTags.Action)
+	}
+	if len(createTags.Resource) != 1 || createTags.Resource[0] != "arn:aws:ec2:*::snapshot/*" {
+		t.Errorf("dlmLifecyclePolicy() CreateTags statement Resource = %v, want the snapshot ARN", createTags.Resource)
+	}
+}
+
+func containsAction(actions iamv1.Actions, action string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,563 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	iamv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/iam/v1alpha1"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-aws/exp/api/v1alpha3"
+)
+
+// TerraformFormat selects the textual representation produced by
+// Template.RenderTerraform.
+type TerraformFormat string
+
+const (
+	// TerraformHCL renders native Terraform configuration language.
+	TerraformHCL TerraformFormat = "hcl"
+	// TerraformJSON renders the JSON variant of the Terraform configuration
+	// language (see the "JSON Configuration Syntax" section of the
+	// Terraform docs).
+	TerraformJSON TerraformFormat = "json"
+)
+
+// terraformResource is a single "<type>" "<name>" { ... } resource block.
+type terraformResource struct {
+	Type string
+	Name string
+	Body map[string]interface{}
+}
+
+// RenderTerraform renders the IAM users, groups, roles, managed policies
+// and instance profiles of RenderCloudFormation as Terraform configuration
+// (HCL or Terraform JSON), building every policy and trust document with
+// the same iamv1.PolicyDocument builders and honouring the same toggles.
+// The controllers, control plane and nodes policies are attached only to
+// the role of the same name, the CSI policy to the control plane role.
+// It does not render the roles' ExtraPolicyAttachments or inline
+// policies, the bootstrap user's policies, the controllers policy's group
+// attachment, or the managed policies of the EKS control plane role.
+func (t Template) RenderTerraform(format TerraformFormat) ([]byte, error) {
+	resources, err := t.terraformResources()
+	if err != nil {
+		return nil, fmt.Errorf("building terraform resources: %w", err)
+	}
+
+	switch format {
+	case TerraformJSON:
+		return renderTerraformJSON(resources)
+	case TerraformHCL:
+		return renderTerraformHCL(resources), nil
+	default:
+		return nil, fmt.Errorf("unknown terraform format %q", format)
+	}
+}
+
+func (t Template) terraformResources() ([]terraformResource, error) {
+	var resources []terraformResource
+
+	if t.Spec.BootstrapUser.Enable {
+		resources = append(resources,
+			terraformResource{
+				Type: "aws_iam_group",
+				Name: "bootstrapper",
+				Body: map[string]interface{}{
+					"name": t.Spec.BootstrapUser.GroupName,
+				},
+			},
+			terraformResource{
+				Type: "aws_iam_user",
+				Name: "bootstrapper",
+				Body: map[string]interface{}{
+					"name": t.Spec.BootstrapUser.UserName,
+					"tags": t.Spec.BootstrapUser.Tags,
+				},
+			},
+			terraformResource{
+				Type: "aws_iam_user_group_membership",
+				Name: "bootstrapper",
+				Body: map[string]interface{}{
+					"user": tfRef("aws_iam_user", "bootstrapper", "name"),
+					"groups": []string{
+						tfRef("aws_iam_group", "bootstrapper", "name"),
+					},
+				},
+			},
+		)
+	}
+
+	controllersPolicyJSON, err := policyDocumentJSON(t.controllersPolicy())
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, terraformResource{
+		Type: "aws_iam_policy",
+		Name: "controllers",
+		Body: map[string]interface{}{
+			"name":   t.NewManagedName("controllers"),
+			"policy": controllersPolicyJSON,
+		},
+	})
+
+	if !t.Spec.ControlPlane.DisableCloudProviderPolicy {
+		controlPlanePolicyJSON, err := policyDocumentJSON(t.cloudProviderControlPlaneAwsPolicy())
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, terraformResource{
+			Type: "aws_iam_policy",
+			Name: "control_plane",
+			Body: map[string]interface{}{
+				"name":   t.NewManagedName("control-plane"),
+				"policy": controlPlanePolicyJSON,
+			},
+		})
+	}
+
+	if !t.Spec.Nodes.DisableCloudProviderPolicy {
+		nodePolicyJSON, err := policyDocumentJSON(t.nodePolicy())
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, terraformResource{
+			Type: "aws_iam_policy",
+			Name: "nodes",
+			Body: map[string]interface{}{
+				"name":   t.NewManagedName("nodes"),
+				"policy": nodePolicyJSON,
+			},
+		})
+	}
+
+	if t.Spec.ControlPlane.EnableCSIPolicy {
+		csiPolicyJSON, err := policyDocumentJSON(t.csiControllerPolicy())
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, terraformResource{
+			Type: "aws_iam_policy",
+			Name: "csi",
+			Body: map[string]interface{}{
+				"name":   t.NewManagedName("csi"),
+				"policy": csiPolicyJSON,
+			},
+		})
+	}
+
+	controlPlaneTrustJSON, err := policyDocumentJSON(t.controlPlaneTrustPolicy())
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, terraformResource{
+		Type: "aws_iam_role",
+		Name: "control_plane",
+		Body: map[string]interface{}{
+			"name":               t.NewManagedName("control-plane"),
+			"assume_role_policy": controlPlaneTrustJSON,
+			"tags":               t.Spec.ControlPlane.Tags,
+		},
+	})
+
+	controllersTrustJSON, err := policyDocumentJSON(t.controllersTrustPolicy())
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, terraformResource{
+		Type: "aws_iam_role",
+		Name: "controllers",
+		Body: map[string]interface{}{
+			"name":               t.NewManagedName("controllers"),
+			"assume_role_policy": controllersTrustJSON,
+			"tags":               t.Spec.ClusterAPIControllers.Tags,
+		},
+	})
+
+	nodeTrustJSON, err := policyDocumentJSON(t.nodeTrustPolicy())
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, terraformResource{
+		Type: "aws_iam_role",
+		Name: "nodes",
+		Body: map[string]interface{}{
+			"name":               t.NewManagedName("nodes"),
+			"assume_role_policy": nodeTrustJSON,
+			"tags":               t.Spec.Nodes.Tags,
+		},
+	})
+
+	resources = append(resources,
+		terraformResource{
+			Type: "aws_iam_instance_profile",
+			Name: "control_plane",
+			Body: map[string]interface{}{
+				"name": t.NewManagedName("control-plane"),
+				"role": tfRef("aws_iam_role", "control_plane", "name"),
+			},
+		},
+		terraformResource{
+			Type: "aws_iam_instance_profile",
+			Name: "controllers",
+			Body: map[string]interface{}{
+				"name": t.NewManagedName("controllers"),
+				"role": tfRef("aws_iam_role", "controllers", "name"),
+			},
+		},
+		terraformResource{
+			Type: "aws_iam_instance_profile",
+			Name: "nodes",
+			Body: map[string]interface{}{
+				"name": t.NewManagedName("nodes"),
+				"role": tfRef("aws_iam_role", "nodes", "name"),
+			},
+		},
+		terraformResource{
+			Type: "aws_iam_role_policy_attachment",
+			Name: "controllers",
+			Body: map[string]interface{}{
+				"role":       tfRef("aws_iam_role", "controllers", "name"),
+				"policy_arn": tfRef("aws_iam_policy", "controllers", "arn"),
+			},
+		},
+	)
+
+	if !t.Spec.ControlPlane.DisableCloudProviderPolicy {
+		resources = append(resources, terraformResource{
+			Type: "aws_iam_role_policy_attachment",
+			Name: "control_plane",
+			Body: map[string]interface{}{
+				"role":       tfRef("aws_iam_role", "control_plane", "name"),
+				"policy_arn": tfRef("aws_iam_policy", "control_plane", "arn"),
+			},
+		})
+	}
+
+	if !t.Spec.Nodes.DisableCloudProviderPolicy {
+		resources = append(resources, terraformResource{
+			Type: "aws_iam_role_policy_attachment",
+			Name: "nodes",
+			Body: map[string]interface{}{
+				"role":       tfRef("aws_iam_role", "nodes", "name"),
+				"policy_arn": tfRef("aws_iam_policy", "nodes", "arn"),
+			},
+		})
+	}
+
+	if t.Spec.ControlPlane.EnableCSIPolicy {
+		resources = append(resources, terraformResource{
+			Type: "aws_iam_role_policy_attachment",
+			Name: "csi",
+			Body: map[string]interface{}{
+				"role":       tfRef("aws_iam_role", "control_plane", "name"),
+				"policy_arn": tfRef("aws_iam_policy", "csi", "arn"),
+			},
+		})
+	}
+
+	if !t.Spec.ManagedControlPlane.Disable {
+		eksTrustJSON, err := policyDocumentJSON(t.eksAssumeRolePolicy())
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, terraformResource{
+			Type: "aws_iam_role",
+			Name: "eks_control_plane",
+			Body: map[string]interface{}{
+				"name":               infrav1exp.DefaultEKSControlPlaneRole,
+				"assume_role_policy": eksTrustJSON,
+				"tags":               t.Spec.ManagedControlPlane.Tags,
+			},
+		})
+	}
+
+	if t.Spec.ControlPlane.EnableDLMPolicy {
+		dlmResources, err := dlmTerraformResources(t)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, dlmResources...)
+	}
+
+	irsaResources, err := t.controllerIAMRoleTerraformResources()
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, irsaResources...)
+
+	wellKnownResources, err := t.wellKnownPolicyTerraformResources()
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, wellKnownResources...)
+
+	return resources, nil
+}
+
+// dlmTerraformResources renders the AWS Data Lifecycle Manager role and
+// managed policy produced by RenderCloudFormation's EnableDLMPolicy block.
+func dlmTerraformResources(t Template) ([]terraformResource, error) {
+	trustJSON, err := policyDocumentJSON(dlmAssumeRolePolicy())
+	if err != nil {
+		return nil, err
+	}
+	policyJSON, err := policyDocumentJSON(dlmLifecyclePolicy())
+	if err != nil {
+		return nil, err
+	}
+
+	return []terraformResource{
+		{
+			Type: "aws_iam_role",
+			Name: "dlm_lifecycle",
+			Body: map[string]interface{}{
+				"name":               t.NewManagedName("dlm-lifecycle"),
+				"assume_role_policy": trustJSON,
+			},
+		},
+		{
+			Type: "aws_iam_policy",
+			Name: "dlm_lifecycle",
+			Body: map[string]interface{}{
+				"name":   t.NewManagedName("dlm-lifecycle"),
+				"policy": policyJSON,
+			},
+		},
+		{
+			Type: "aws_iam_role_policy_attachment",
+			Name: "dlm_lifecycle",
+			Body: map[string]interface{}{
+				"role":       tfRef("aws_iam_role", "dlm_lifecycle", "name"),
+				"policy_arn": tfRef("aws_iam_policy", "dlm_lifecycle", "arn"),
+			},
+		},
+	}, nil
+}
+
+// controllerIAMRoleTerraformResources renders the IRSA-trusted roles
+// produced by RenderCloudFormation's controllerIAMRoles for
+// Spec.ControllerIAMRoles.
+func (t Template) controllerIAMRoleTerraformResources() ([]terraformResource, error) {
+	var resources []terraformResource
+	if len(t.Spec.OIDCProviders) == 0 {
+		return resources, nil
+	}
+
+	for _, cr := range t.Spec.ControllerIAMRoles {
+		var statements []iamv1.StatementEntry
+		for _, provider := range t.Spec.OIDCProviders {
+			doc, err := federatedTrustPolicy(provider, cr.ServiceAccount)
+			if err != nil {
+				return nil, fmt.Errorf("building trust policy for controller role %s: %w", cr.Name, err)
+			}
+			statements = append(statements, doc.Statement...)
+		}
+
+		trustJSON, err := policyDocumentJSON(&iamv1.PolicyDocument{Version: iamv1.CurrentVersion, Statement: statements})
+		if err != nil {
+			return nil, err
+		}
+
+		name := terraformSnakeCase(cr.Name) + "_irsa"
+		resources = append(resources, terraformResource{
+			Type: "aws_iam_role",
+			Name: name,
+			Body: map[string]interface{}{
+				"name":               t.NewManagedName(cr.Name + "-irsa"),
+				"assume_role_policy": trustJSON,
+			},
+		})
+
+		for i, policyArn := range cr.ManagedPolicyArns {
+			resources = append(resources, terraformResource{
+				Type: "aws_iam_role_policy_attachment",
+				Name: fmt.Sprintf("%s_%d", name, i),
+				Body: map[string]interface{}{
+					"role":       tfRef("aws_iam_role", name, "name"),
+					"policy_arn": policyArn,
+				},
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+// wellKnownPolicyTerraformResources renders the managed policies (and,
+// when OIDC providers are configured, the matching IRSA roles) produced
+// by RenderCloudFormation's wellKnownPolicyResources for every addon
+// enabled in Spec.WellKnownPolicies.
+func (t Template) wellKnownPolicyTerraformResources() ([]terraformResource, error) {
+	var resources []terraformResource
+
+	for _, wkp := range wellKnownPolicies {
+		if !wkp.enabled(t.Spec.WellKnownPolicies) {
+			continue
+		}
+
+		policyJSON, err := policyDocumentJSON(wkp.document())
+		if err != nil {
+			return nil, err
+		}
+
+		policyName := terraformSnakeCase(wkp.name)
+		resources = append(resources, terraformResource{
+			Type: "aws_iam_policy",
+			Name: policyName,
+			Body: map[string]interface{}{
+				"name":   t.NewManagedName(wkp.name),
+				"policy": policyJSON,
+			},
+		})
+
+		if len(t.Spec.OIDCProviders) == 0 {
+			continue
+		}
+
+		var statements []iamv1.StatementEntry
+		for _, provider := range t.Spec.OIDCProviders {
+			doc, err := federatedTrustPolicy(provider, wkp.serviceAccount)
+			if err != nil {
+				return nil, fmt.Errorf("building trust policy for well-known policy %s: %w", wkp.name, err)
+			}
+			statements = append(statements, doc.Statement...)
+		}
+
+		trustJSON, err := policyDocumentJSON(&iamv1.PolicyDocument{Version: iamv1.CurrentVersion, Statement: statements})
+		if err != nil {
+			return nil, err
+		}
+
+		roleName := policyName + "_irsa"
+		resources = append(resources,
+			terraformResource{
+				Type: "aws_iam_role",
+				Name: roleName,
+				Body: map[string]interface{}{
+					"name":               t.NewManagedName(wkp.name + "-irsa"),
+					"assume_role_policy": trustJSON,
+				},
+			},
+			terraformResource{
+				Type: "aws_iam_role_policy_attachment",
+				Name: roleName,
+				Body: map[string]interface{}{
+					"role":       tfRef("aws_iam_role", roleName, "name"),
+					"policy_arn": tfRef("aws_iam_policy", policyName, "arn"),
+				},
+			},
+		)
+	}
+
+	return resources, nil
+}
+
+// tfRef builds a Terraform interpolation reference to an attribute of
+// another resource in this configuration, e.g. "${aws_iam_role.nodes.name}".
+func tfRef(resourceType, name, attr string) string {
+	return fmt.Sprintf("${%s.%s.%s}", resourceType, name, attr)
+}
+
+// terraformSnakeCase converts a kebab-case config value (e.g. a
+// ControllerIAMRole or well-known addon name) into the snake_case form
+// Terraform resource names require.
+func terraformSnakeCase(s string) string {
+	return strings.ReplaceAll(s, "-", "_")
+}
+
+// policyDocumentJSON marshals an iamv1.PolicyDocument to the JSON string
+// Terraform's aws_iam_policy/aws_iam_role "policy"/"assume_role_policy"
+// attributes expect.
+func policyDocumentJSON(doc *iamv1.PolicyDocument) (string, error) {
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("marshaling policy document: %w", err)
+	}
+	return string(out), nil
+}
+
+func renderTerraformJSON(resources []terraformResource) ([]byte, error) {
+	byType := map[string]map[string]interface{}{}
+	for _, r := range resources {
+		if byType[r.Type] == nil {
+			byType[r.Type] = map[string]interface{}{}
+		}
+		byType[r.Type][r.Name] = r.Body
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"resource": byType,
+	}, "", "  ")
+}
+
+func renderTerraformHCL(resources []terraformResource) []byte {
+	var out strings.Builder
+	for i, r := range resources {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "resource %q %q {\n", r.Type, r.Name)
+		writeHCLBody(&out, r.Body, "  ")
+		out.WriteString("}\n")
+	}
+	return []byte(out.String())
+}
+
+func writeHCLBody(out *strings.Builder, body map[string]interface{}, indent string) {
+	keys := make([]string, 0, len(body))
+	for k := range body {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(out, "%s%s = %s\n", indent, k, hclValue(body[k]))
+	}
+}
+
+func hclValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", val)
+	case []string:
+		parts := make([]string, len(val))
+		for i, s := range val {
+			parts[i] = fmt.Sprintf("%q", s)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]string:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = fmt.Sprintf("%q = %q", k, val[k])
+		}
+		return "{\n    " + strings.Join(parts, "\n    ") + "\n  }"
+	case bool:
+		return fmt.Sprintf("%t", val)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%q", fmt.Sprintf("%v", val))
+	}
+}
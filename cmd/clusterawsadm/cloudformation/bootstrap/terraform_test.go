@@ -0,0 +1,174 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"strings"
+	"testing"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/bootstrap/v1alpha1"
+	iamv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/iam/v1alpha1"
+)
+
+func newTestTemplate() Template {
+	suffix := ""
+	return Template{Spec: &bootstrapv1.AWSIAMConfigurationSpec{NameSuffix: &suffix}}
+}
+
+func TestTfRef(t *testing.T) {
+	got := tfRef("aws_iam_role", "nodes", "name")
+	want := "${aws_iam_role.nodes.name}"
+	if got != want {
+		t.Errorf("tfRef() = %q, want %q", got, want)
+	}
+}
+
+func TestPolicyDocumentJSON(t *testing.T) {
+	doc := &iamv1.PolicyDocument{
+		Version: iamv1.CurrentVersion,
+		Statement: []iamv1.StatementEntry{
+			{
+				Effect: iamv1.EffectAllow,
+				Action: iamv1.Actions{"sts:AssumeRole"},
+			},
+		},
+	}
+
+	out, err := policyDocumentJSON(doc)
+	if err != nil {
+		t.Fatalf("policyDocumentJSON() error = %v", err)
+	}
+	if !strings.Contains(out, `"sts:AssumeRole"`) {
+		t.Errorf("policyDocumentJSON() = %s, want it to contain the statement action", out)
+	}
+}
+
+func TestRenderTerraformJSON(t *testing.T) {
+	resources := []terraformResource{
+		{Type: "aws_iam_role", Name: "nodes", Body: map[string]interface{}{"name": "nodes"}},
+	}
+
+	out, err := renderTerraformJSON(resources)
+	if err != nil {
+		t.Fatalf("renderTerraformJSON() error = %v", err)
+	}
+	if !strings.Contains(string(out), `"aws_iam_role"`) || !strings.Contains(string(out), `"nodes"`) {
+		t.Errorf("renderTerraformJSON() = %s, want it to contain the resource type and name", out)
+	}
+}
+
+func TestRenderTerraformHCL(t *testing.T) {
+	resources := []terraformResource{
+		{Type: "aws_iam_role", Name: "nodes", Body: map[string]interface{}{"name": "nodes"}},
+	}
+
+	out := renderTerraformHCL(resources)
+	want := `resource "aws_iam_role" "nodes" {`
+	if !strings.Contains(string(out), want) {
+		t.Errorf("renderTerraformHCL() = %s, want it to contain %q", out, want)
+	}
+}
+
+func TestTerraformSnakeCase(t *testing.T) {
+	if got := terraformSnakeCase("aws-load-balancer-controller"); got != "aws_load_balancer_controller" {
+		t.Errorf("terraformSnakeCase() = %q, want aws_load_balancer_controller", got)
+	}
+}
+
+func TestDlmTerraformResources(t *testing.T) {
+	resources, err := dlmTerraformResources(newTestTemplate())
+	if err != nil {
+		t.Fatalf("dlmTerraformResources() error = %v", err)
+	}
+
+	want := map[string]string{
+		"aws_iam_role":                   "dlm_lifecycle",
+		"aws_iam_policy":                 "dlm_lifecycle",
+		"aws_iam_role_policy_attachment": "dlm_lifecycle",
+	}
+	if len(resources) != len(want) {
+		t.Fatalf("dlmTerraformResources() returned %d resources, want %d", len(resources), len(want))
+	}
+	for _, r := range resources {
+		if want[r.Type] != r.Name {
+			t.Errorf("dlmTerraformResources() unexpected resource %s.%s", r.Type, r.Name)
+		}
+	}
+}
+
+func TestControllerIAMRoleTerraformResources(t *testing.T) {
+	tmpl := newTestTemplate()
+	tmpl.Spec.ControllerIAMRoles = []bootstrapv1.ControllerIAMRole{
+		{
+			Name:              "my-controller",
+			ServiceAccount:    bootstrapv1.ServiceAccount{Namespace: "kube-system", Name: "my-controller"},
+			ManagedPolicyArns: []string{"arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess"},
+		},
+	}
+
+	if resources, err := tmpl.controllerIAMRoleTerraformResources(); err != nil {
+		t.Fatalf("controllerIAMRoleTerraformResources() error = %v", err)
+	} else if len(resources) != 0 {
+		t.Errorf("controllerIAMRoleTerraformResources() with no OIDCProviders = %d resources, want 0", len(resources))
+	}
+
+	tmpl.Spec.OIDCProviders = []bootstrapv1.OIDCProvider{
+		{ARN: "arn:aws:iam::111111111111:oidc-provider/oidc.eks.us-east-1.amazonaws.com/id/EXAMPLE"},
+	}
+
+	resources, err := tmpl.controllerIAMRoleTerraformResources()
+	if err != nil {
+		t.Fatalf("controllerIAMRoleTerraformResources() error = %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("controllerIAMRoleTerraformResources() = %d resources, want 2 (role + attachment)", len(resources))
+	}
+	if resources[0].Type != "aws_iam_role" || resources[0].Name != "my_controller_irsa" {
+		t.Errorf("controllerIAMRoleTerraformResources() role = %s.%s, want aws_iam_role.my_controller_irsa", resources[0].Type, resources[0].Name)
+	}
+	if resources[1].Type != "aws_iam_role_policy_attachment" || resources[1].Name != "my_controller_irsa_0" {
+		t.Errorf("controllerIAMRoleTerraformResources() attachment = %s.%s, want aws_iam_role_policy_attachment.my_controller_irsa_0", resources[1].Type, resources[1].Name)
+	}
+}
+
+func TestWellKnownPolicyTerraformResources(t *testing.T) {
+	tmpl := newTestTemplate()
+	tmpl.Spec.WellKnownPolicies.AWSLoadBalancerController = true
+
+	resources, err := tmpl.wellKnownPolicyTerraformResources()
+	if err != nil {
+		t.Fatalf("wellKnownPolicyTerraformResources() error = %v", err)
+	}
+	if len(resources) != 1 {
+		t.Fatalf("wellKnownPolicyTerraformResources() with no OIDCProviders = %d resources, want 1 (policy only)", len(resources))
+	}
+	if resources[0].Type != "aws_iam_policy" || resources[0].Name != "aws_load_balancer_controller" {
+		t.Errorf("wellKnownPolicyTerraformResources() policy = %s.%s, want aws_iam_policy.aws_load_balancer_controller", resources[0].Type, resources[0].Name)
+	}
+
+	tmpl.Spec.OIDCProviders = []bootstrapv1.OIDCProvider{
+		{ARN: "arn:aws:iam::111111111111:oidc-provider/oidc.eks.us-east-1.amazonaws.com/id/EXAMPLE"},
+	}
+
+	resources, err = tmpl.wellKnownPolicyTerraformResources()
+	if err != nil {
+		t.Fatalf("wellKnownPolicyTerraformResources() error = %v", err)
+	}
+	if len(resources) != 3 {
+		t.Fatalf("wellKnownPolicyTerraformResources() with OIDCProviders = %d resources, want 3 (policy, role, attachment)", len(resources))
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	iamv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/iam/v1alpha1"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/bootstrap/v1alpha1"
+)
+
+// mergeTrustPolicy layers a role's user-configured TrustPolicy on top of
+// its default principal(s), so the role can still be assumed by the
+// default principal even when extra principals are added.
+func mergeTrustPolicy(base bootstrapv1.TrustPolicy, override *bootstrapv1.TrustPolicy) bootstrapv1.TrustPolicy {
+	if override == nil {
+		return base
+	}
+
+	merged := base
+	merged.Services = append(append([]string{}, base.Services...), override.Services...)
+	merged.AWSPrincipals = append(append([]string{}, base.AWSPrincipals...), override.AWSPrincipals...)
+	merged.FederatedPrincipals = append(append([]bootstrapv1.FederatedPrincipal{}, base.FederatedPrincipals...), override.FederatedPrincipals...)
+	if override.Conditions != nil {
+		merged.Conditions = override.Conditions
+	}
+	return merged
+}
+
+func (t Template) controlPlaneTrustPolicy() *iamv1.PolicyDocument {
+	return assumeRolePolicy(mergeTrustPolicy(bootstrapv1.TrustPolicy{Services: []string{"ec2.amazonaws.com"}}, t.Spec.ControlPlane.TrustPolicy))
+}
+
+func (t Template) controllersTrustPolicy() *iamv1.PolicyDocument {
+	return assumeRolePolicy(mergeTrustPolicy(bootstrapv1.TrustPolicy{Services: []string{"ec2.amazonaws.com"}}, t.Spec.ClusterAPIControllers.TrustPolicy))
+}
+
+func (t Template) nodeTrustPolicy() *iamv1.PolicyDocument {
+	return assumeRolePolicy(mergeTrustPolicy(bootstrapv1.TrustPolicy{Services: []string{"ec2.amazonaws.com"}}, t.Spec.Nodes.TrustPolicy))
+}
+
+func (t Template) eksAssumeRolePolicy() *iamv1.PolicyDocument {
+	return assumeRolePolicy(mergeTrustPolicy(bootstrapv1.TrustPolicy{Services: []string{"eks.amazonaws.com"}}, t.Spec.ManagedControlPlane.TrustPolicy))
+}
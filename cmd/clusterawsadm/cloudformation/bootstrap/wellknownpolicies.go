@@ -0,0 +1,295 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awslabs/goformation/v4/cloudformation"
+	cfn_iam "github.com/awslabs/goformation/v4/cloudformation/iam"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/bootstrap/v1alpha1"
+	iamv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/iam/v1alpha1"
+)
+
+// wellKnownPolicy describes one addon covered by Spec.WellKnownPolicies.
+type wellKnownPolicy struct {
+	name           string
+	enabled        func(bootstrapv1.WellKnownPolicies) bool
+	serviceAccount bootstrapv1.ServiceAccount
+	document       func() *iamv1.PolicyDocument
+}
+
+// wellKnownPolicies is the catalog of pre-authored managed policies for
+// common EKS addons. The statements below are intentionally conservative
+// starting points for each addon's documented IAM policy; operators who
+// need more can still use ExtraPolicyAttachments.
+var wellKnownPolicies = []wellKnownPolicy{
+	{
+		name:           "aws-load-balancer-controller",
+		enabled:        func(w bootstrapv1.WellKnownPolicies) bool { return w.AWSLoadBalancerController },
+		serviceAccount: bootstrapv1.ServiceAccount{Namespace: "kube-system", Name: "aws-load-balancer-controller"},
+		document: func() *iamv1.PolicyDocument {
+			return &iamv1.PolicyDocument{
+				Version: iamv1.CurrentVersion,
+				Statement: []iamv1.StatementEntry{
+					{
+						Effect: iamv1.EffectAllow,
+						Action: iamv1.Actions{
+							"elasticloadbalancing:*",
+							"ec2:DescribeAccountAttributes",
+							"ec2:DescribeInstances",
+							"ec2:DescribeSecurityGroups",
+							"ec2:DescribeSubnets",
+							"ec2:DescribeVpcs",
+							"acm:ListCertificates",
+							"acm:DescribeCertificate",
+							"wafv2:GetWebACL",
+							"wafv2:AssociateWebACL",
+							"wafv2:DisassociateWebACL",
+						},
+						Resource: iamv1.Actions{"*"},
+					},
+				},
+			}
+		},
+	},
+	{
+		name:           "cluster-autoscaler",
+		enabled:        func(w bootstrapv1.WellKnownPolicies) bool { return w.ClusterAutoscaler },
+		serviceAccount: bootstrapv1.ServiceAccount{Namespace: "kube-system", Name: "cluster-autoscaler"},
+		document: func() *iamv1.PolicyDocument {
+			return &iamv1.PolicyDocument{
+				Version: iamv1.CurrentVersion,
+				Statement: []iamv1.StatementEntry{
+					{
+						Effect: iamv1.EffectAllow,
+						Action: iamv1.Actions{
+							"autoscaling:DescribeAutoScalingGroups",
+							"autoscaling:DescribeAutoScalingInstances",
+							"autoscaling:DescribeLaunchConfigurations",
+							"autoscaling:DescribeTags",
+							"autoscaling:SetDesiredCapacity",
+							"autoscaling:TerminateInstanceInAutoScalingGroup",
+							"ec2:DescribeLaunchTemplateVersions",
+						},
+						Resource: iamv1.Actions{"*"},
+					},
+				},
+			}
+		},
+	},
+	{
+		name:           "external-dns",
+		enabled:        func(w bootstrapv1.WellKnownPolicies) bool { return w.ExternalDNS },
+		serviceAccount: bootstrapv1.ServiceAccount{Namespace: "kube-system", Name: "external-dns"},
+		document: func() *iamv1.PolicyDocument {
+			return &iamv1.PolicyDocument{
+				Version: iamv1.CurrentVersion,
+				Statement: []iamv1.StatementEntry{
+					{
+						Effect:   iamv1.EffectAllow,
+						Action:   iamv1.Actions{"route53:ChangeResourceRecordSets"},
+						Resource: iamv1.Actions{"arn:aws:route53:::hostedzone/*"},
+					},
+					{
+						Effect: iamv1.EffectAllow,
+						Action: iamv1.Actions{
+							"route53:ListHostedZones",
+							"route53:ListResourceRecordSets",
+						},
+						Resource: iamv1.Actions{"*"},
+					},
+				},
+			}
+		},
+	},
+	{
+		name:           "cert-manager",
+		enabled:        func(w bootstrapv1.WellKnownPolicies) bool { return w.CertManager },
+		serviceAccount: bootstrapv1.ServiceAccount{Namespace: "cert-manager", Name: "cert-manager"},
+		document: func() *iamv1.PolicyDocument {
+			return &iamv1.PolicyDocument{
+				Version: iamv1.CurrentVersion,
+				Statement: []iamv1.StatementEntry{
+					{
+						Effect:   iamv1.EffectAllow,
+						Action:   iamv1.Actions{"route53:GetChange"},
+						Resource: iamv1.Actions{"arn:aws:route53:::change/*"},
+					},
+					{
+						Effect: iamv1.EffectAllow,
+						Action: iamv1.Actions{
+							"route53:ChangeResourceRecordSets",
+							"route53:ListResourceRecordSets",
+						},
+						Resource: iamv1.Actions{"arn:aws:route53:::hostedzone/*"},
+					},
+					{
+						Effect:   iamv1.EffectAllow,
+						Action:   iamv1.Actions{"route53:ListHostedZonesByName"},
+						Resource: iamv1.Actions{"*"},
+					},
+				},
+			}
+		},
+	},
+	{
+		name:           "ebs-csi-controller",
+		enabled:        func(w bootstrapv1.WellKnownPolicies) bool { return w.EBSCSIController },
+		serviceAccount: bootstrapv1.ServiceAccount{Namespace: "kube-system", Name: "ebs-csi-controller-sa"},
+		document: func() *iamv1.PolicyDocument {
+			return csiControllerPolicyDocument("ec2:CreateVolume", "ec2:DeleteVolume", "ec2:AttachVolume", "ec2:DetachVolume",
+				"ec2:CreateSnapshot", "ec2:DeleteSnapshot", "ec2:DescribeVolumes", "ec2:DescribeSnapshots",
+				"ec2:DescribeInstances", "ec2:DescribeAvailabilityZones", "ec2:CreateTags")
+		},
+	},
+	{
+		name:           "efs-csi-controller",
+		enabled:        func(w bootstrapv1.WellKnownPolicies) bool { return w.EFSCSIController },
+		serviceAccount: bootstrapv1.ServiceAccount{Namespace: "kube-system", Name: "efs-csi-controller-sa"},
+		document: func() *iamv1.PolicyDocument {
+			return csiControllerPolicyDocument("elasticfilesystem:DescribeAccessPoints", "elasticfilesystem:DescribeFileSystems",
+				"elasticfilesystem:CreateAccessPoint", "elasticfilesystem:DeleteAccessPoint", "elasticfilesystem:TagResource")
+		},
+	},
+	{
+		name:           "image-builder",
+		enabled:        func(w bootstrapv1.WellKnownPolicies) bool { return w.ImageBuilder },
+		serviceAccount: bootstrapv1.ServiceAccount{Namespace: "eks-image-builder", Name: "image-builder"},
+		document: func() *iamv1.PolicyDocument {
+			return &iamv1.PolicyDocument{
+				Version: iamv1.CurrentVersion,
+				Statement: []iamv1.StatementEntry{
+					{
+						Effect: iamv1.EffectAllow,
+						Action: iamv1.Actions{
+							"ec2:CopyImage",
+							"ec2:CreateImage",
+							"ec2:DeregisterImage",
+							"ec2:DescribeImages",
+							"ec2:ModifyImageAttribute",
+							"ec2:RegisterImage",
+						},
+						Resource: iamv1.Actions{"*"},
+					},
+				},
+			}
+		},
+	},
+	{
+		name:           "auto-scaler",
+		enabled:        func(w bootstrapv1.WellKnownPolicies) bool { return w.AutoScaler },
+		serviceAccount: bootstrapv1.ServiceAccount{Namespace: "kube-system", Name: "cluster-autoscaler-aws"},
+		document: func() *iamv1.PolicyDocument {
+			return &iamv1.PolicyDocument{
+				Version: iamv1.CurrentVersion,
+				Statement: []iamv1.StatementEntry{
+					{
+						Effect: iamv1.EffectAllow,
+						Action: iamv1.Actions{
+							"autoscaling:DescribeAutoScalingGroups",
+							"autoscaling:UpdateAutoScalingGroup",
+							"ec2:DescribeInstanceTypes",
+						},
+						Resource: iamv1.Actions{"*"},
+					},
+				},
+			}
+		},
+	},
+}
+
+func csiControllerPolicyDocument(actions ...string) *iamv1.PolicyDocument {
+	return &iamv1.PolicyDocument{
+		Version: iamv1.CurrentVersion,
+		Statement: []iamv1.StatementEntry{
+			{
+				Effect:   iamv1.EffectAllow,
+				Action:   iamv1.Actions(actions),
+				Resource: iamv1.Actions{"*"},
+			},
+		},
+	}
+}
+
+// wellKnownPolicyResources builds the AWS::IAM::ManagedPolicy (and, when
+// an OIDC provider is configured, the matching IRSA AWS::IAM::Role)
+// resources for every addon enabled in Spec.WellKnownPolicies.
+func (t Template) wellKnownPolicyResources() (map[string]cloudformation.Resource, error) {
+	resources := map[string]cloudformation.Resource{}
+
+	for _, wkp := range wellKnownPolicies {
+		if !wkp.enabled(t.Spec.WellKnownPolicies) {
+			continue
+		}
+
+		policyResourceName := wellKnownPolicyResourceName(wkp.name)
+		resources[policyResourceName] = &cfn_iam.ManagedPolicy{
+			ManagedPolicyName: t.NewManagedName(wkp.name),
+			Description:       fmt.Sprintf("Well-known policy for the %s EKS addon", wkp.name),
+			PolicyDocument:    wkp.document(),
+		}
+
+		if len(t.Spec.OIDCProviders) == 0 {
+			continue
+		}
+
+		var statements []iamv1.StatementEntry
+		for _, provider := range t.Spec.OIDCProviders {
+			doc, err := federatedTrustPolicy(provider, wkp.serviceAccount)
+			if err != nil {
+				return nil, fmt.Errorf("building trust policy for well-known policy %s: %w", wkp.name, err)
+			}
+			statements = append(statements, doc.Statement...)
+		}
+
+		resources[wellKnownRoleResourceName(wkp.name)] = &cfn_iam.Role{
+			RoleName: t.NewManagedName(wkp.name + "-irsa"),
+			AssumeRolePolicyDocument: &iamv1.PolicyDocument{
+				Version:   iamv1.CurrentVersion,
+				Statement: statements,
+			},
+			ManagedPolicyArns: []string{cloudformation.GetAtt(policyResourceName, "Arn")},
+		}
+	}
+
+	return resources, nil
+}
+
+func wellKnownPolicyResourceName(addon string) string {
+	return "AWSIAMManagedPolicy" + kebabToPascal(addon)
+}
+
+func wellKnownRoleResourceName(addon string) string {
+	return "AWSIAMRole" + kebabToPascal(addon) + "IRSA"
+}
+
+// kebabToPascal converts a kebab-case addon name (e.g.
+// "aws-load-balancer-controller") into the PascalCase form CloudFormation
+// logical resource names use (e.g. "AwsLoadBalancerController").
+func kebabToPascal(s string) string {
+	parts := strings.Split(s, "-")
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		parts[i] = strings.ToUpper(p[:1]) + p[1:]
+	}
+	return strings.Join(parts, "")
+}
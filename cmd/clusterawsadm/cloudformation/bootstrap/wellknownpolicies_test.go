@@ -0,0 +1,43 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import "testing"
+
+func TestKebabToPascal(t *testing.T) {
+	cases := map[string]string{
+		"aws-load-balancer-controller": "AwsLoadBalancerController",
+		"cert-manager":                 "CertManager",
+		"autoscaler":                   "Autoscaler",
+		"":                             "",
+	}
+
+	for in, want := range cases {
+		if got := kebabToPascal(in); got != want {
+			t.Errorf("kebabToPascal(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWellKnownPolicyResourceNames(t *testing.T) {
+	if got, want := wellKnownPolicyResourceName("ebs-csi-controller"), "AWSIAMManagedPolicyEbsCsiController"; got != want {
+		t.Errorf("wellKnownPolicyResourceName() = %q, want %q", got, want)
+	}
+	if got, want := wellKnownRoleResourceName("ebs-csi-controller"), "AWSIAMRoleEbsCsiControllerIRSA"; got != want {
+		t.Errorf("wellKnownRoleResourceName() = %q, want %q", got, want)
+	}
+}
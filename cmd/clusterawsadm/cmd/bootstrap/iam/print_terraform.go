@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package iam
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/api/bootstrap/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-aws/cmd/clusterawsadm/cloudformation/bootstrap"
+)
+
+// printTerraformOutput is the flag value for --output on print-terraform.
+var printTerraformOutput string
+
+// PrintTerraformCmd prints out the bootstrap IAM resources of the default
+// configuration as rendered by Template.RenderTerraform, which covers a
+// subset of the CloudFormation stack. It should be registered alongside
+// print-cloudformation under the "bootstrap iam" command.
+func PrintTerraformCmd() *cobra.Command {
+	newCmd := &cobra.Command{
+		Use:   "print-terraform",
+		Short: "Print out Terraform configuration for bootstrapping",
+		Long: `Print out Terraform configuration for bootstrapping the IAM
+roles, users, and policies necessary to use Cluster API Provider AWS, as
+either native Terraform (HCL) or the Terraform JSON configuration syntax.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPrintTerraform()
+		},
+		SilenceUsage: true,
+	}
+	newCmd.Flags().StringVarP(&printTerraformOutput, "output", "o", "hcl", "Output format, one of: hcl, json")
+	return newCmd
+}
+
+func runPrintTerraform() error {
+	var format bootstrap.TerraformFormat
+	switch printTerraformOutput {
+	case "hcl":
+		format = bootstrap.TerraformHCL
+	case "json":
+		format = bootstrap.TerraformJSON
+	default:
+		return fmt.Errorf("unsupported output format %q, must be one of: hcl, json", printTerraformOutput)
+	}
+
+	configuration := bootstrapv1.NewAWSIAMConfiguration()
+	template := bootstrap.Template{Spec: &configuration.Spec}
+
+	rendered, err := template.RenderTerraform(format)
+	if err != nil {
+		return fmt.Errorf("rendering terraform configuration: %w", err)
+	}
+
+	fmt.Println(string(rendered))
+	return nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package converters contains conversion functions shared by the
+// clusterawsadm CloudFormation/Terraform renderers.
+package converters
+
+import (
+	"sort"
+
+	"github.com/awslabs/goformation/v4/cloudformation/tags"
+)
+
+// MapToCloudFormationTags converts a map of tag key/value pairs into the
+// slice of tags.Tag goformation expects, in a deterministic order.
+func MapToCloudFormationTags(in map[string]string) []tags.Tag {
+	if len(in) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(in))
+	for k := range in {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]tags.Tag, 0, len(in))
+	for _, k := range keys {
+		out = append(out, tags.Tag{Key: k, Value: in[k]})
+	}
+	return out
+}